@@ -0,0 +1,164 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package azure registers the "azure" blobserver storage type, storing blobs
+// in an Azure Blob Storage container.
+package azure // import "perkeep.org/pkg/blobserver/azure"
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/storage"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/blobserver"
+	"perkeep.org/pkg/jsonconfig"
+)
+
+type storageBackend struct {
+	container *storage.Container
+	// dirPrefix, if non-empty, is prepended (with a trailing "/") to every
+	// blob name, so that this storage instance is confined to a
+	// subdirectory of the container.
+	dirPrefix string
+}
+
+func newFromConfig(_ blobserver.Loader, config jsonconfig.Obj) (blobserver.Storage, error) {
+	var (
+		accountName   = config.RequiredString("account_name")
+		accountKey    = config.RequiredString("account_key")
+		containerName = config.RequiredString("container")
+	)
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var dirPrefix string
+	if parts := strings.SplitN(containerName, "/", 2); len(parts) > 1 {
+		containerName, dirPrefix = parts[0], parts[1]
+		if !strings.HasSuffix(dirPrefix, "/") {
+			dirPrefix += "/"
+		}
+	}
+
+	client, err := storage.NewBasicClient(accountName, accountKey)
+	if err != nil {
+		return nil, err
+	}
+	bs := client.GetBlobService()
+	cnt := bs.GetContainerReference(containerName)
+	if _, err := cnt.CreateIfNotExists(nil); err != nil {
+		return nil, err
+	}
+	return &storageBackend{
+		container: cnt,
+		dirPrefix: dirPrefix,
+	}, nil
+}
+
+func init() {
+	blobserver.RegisterStorageConstructor("azure", blobserver.StorageConstructor(newFromConfig))
+}
+
+func (s *storageBackend) blobName(br blob.Ref) string {
+	return s.dirPrefix + br.String()
+}
+
+func (s *storageBackend) Fetch(ctx context.Context, br blob.Ref) (io.ReadCloser, uint32, error) {
+	b := s.container.GetBlobReference(s.blobName(br))
+	if ok, err := b.Exists(); err != nil {
+		return nil, 0, err
+	} else if !ok {
+		return nil, 0, os.ErrNotExist
+	}
+	if err := b.GetProperties(nil); err != nil {
+		return nil, 0, err
+	}
+	rc, err := b.Get(nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return rc, uint32(b.Properties.ContentLength), nil
+}
+
+func (s *storageBackend) StatBlobs(ctx context.Context, blobs []blob.Ref, fn func(blob.SizedRef) error) error {
+	for _, br := range blobs {
+		b := s.container.GetBlobReference(s.blobName(br))
+		ok, err := b.Exists()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := b.GetProperties(nil); err != nil {
+			return err
+		}
+		if err := fn(blob.SizedRef{Ref: br, Size: uint32(b.Properties.ContentLength)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storageBackend) ReceiveBlob(ctx context.Context, br blob.Ref, source io.Reader) (blob.SizedRef, error) {
+	b := s.container.GetBlobReference(s.blobName(br))
+	if err := b.CreateBlockBlobFromReader(source, nil); err != nil {
+		return blob.SizedRef{}, err
+	}
+	if err := b.GetProperties(nil); err != nil {
+		return blob.SizedRef{}, err
+	}
+	return blob.SizedRef{Ref: br, Size: uint32(b.Properties.ContentLength)}, nil
+}
+
+func (s *storageBackend) RemoveBlobs(ctx context.Context, blobs []blob.Ref) error {
+	for _, br := range blobs {
+		b := s.container.GetBlobReference(s.blobName(br))
+		if _, err := b.DeleteIfExists(nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storageBackend) EnumerateBlobs(ctx context.Context, dest chan<- blob.SizedRef, after string, limit int) error {
+	defer close(dest)
+	resp, err := s.container.ListBlobs(storage.ListBlobsParameters{
+		Prefix:     s.dirPrefix,
+		Marker:     s.dirPrefix + after,
+		MaxResults: uint(limit),
+	})
+	if err != nil {
+		return err
+	}
+	for _, b := range resp.Blobs {
+		name := strings.TrimPrefix(b.Name, s.dirPrefix)
+		br, ok := blob.Parse(name)
+		if !ok {
+			continue
+		}
+		select {
+		case dest <- blob.SizedRef{Ref: br, Size: uint32(b.Properties.ContentLength)}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
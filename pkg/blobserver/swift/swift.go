@@ -0,0 +1,161 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package swift registers the "swift" blobserver storage type, storing blobs
+// in an OpenStack Swift container.
+package swift // import "perkeep.org/pkg/blobserver/swift"
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ncw/swift"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/blobserver"
+	"perkeep.org/pkg/jsonconfig"
+)
+
+type storage struct {
+	conn      *swift.Connection
+	container string
+	// dirPrefix, if non-empty, is prepended (with a trailing "/") to every
+	// object name, so that this storage instance is confined to a
+	// subdirectory of the container.
+	dirPrefix string
+}
+
+func newFromConfig(_ blobserver.Loader, config jsonconfig.Obj) (blobserver.Storage, error) {
+	var (
+		userName  = config.RequiredString("user_name")
+		apiKey    = config.RequiredString("api_key")
+		authURL   = config.RequiredString("auth_url")
+		container = config.RequiredString("container")
+		tenant    = config.OptionalString("tenant", "")
+		region    = config.OptionalString("region", "")
+	)
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	var dirPrefix string
+	if parts := strings.SplitN(container, "/", 2); len(parts) > 1 {
+		container, dirPrefix = parts[0], parts[1]
+		if !strings.HasSuffix(dirPrefix, "/") {
+			dirPrefix += "/"
+		}
+	}
+
+	conn := &swift.Connection{
+		UserName: userName,
+		ApiKey:   apiKey,
+		AuthUrl:  authURL,
+		Tenant:   tenant,
+		Region:   region,
+	}
+	if err := conn.Authenticate(); err != nil {
+		return nil, fmt.Errorf("swift: could not authenticate: %v", err)
+	}
+	if _, _, err := conn.Container(container); err != nil {
+		return nil, fmt.Errorf("swift: could not stat container %q: %v", container, err)
+	}
+	return &storage{
+		conn:      conn,
+		container: container,
+		dirPrefix: dirPrefix,
+	}, nil
+}
+
+func init() {
+	blobserver.RegisterStorageConstructor("swift", blobserver.StorageConstructor(newFromConfig))
+}
+
+func (s *storage) objectName(br blob.Ref) string {
+	return s.dirPrefix + br.String()
+}
+
+func (s *storage) Fetch(ctx context.Context, br blob.Ref) (io.ReadCloser, uint32, error) {
+	rc, obj, err := s.conn.ObjectOpen(s.container, s.objectName(br), true, nil)
+	if err != nil {
+		if err == swift.ObjectNotFound {
+			return nil, 0, os.ErrNotExist
+		}
+		return nil, 0, err
+	}
+	return rc, uint32(obj.Bytes), nil
+}
+
+func (s *storage) StatBlobs(ctx context.Context, blobs []blob.Ref, fn func(blob.SizedRef) error) error {
+	for _, br := range blobs {
+		info, _, err := s.conn.Object(s.container, s.objectName(br))
+		if err == swift.ObjectNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(blob.SizedRef{Ref: br, Size: uint32(info.Bytes)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storage) ReceiveBlob(ctx context.Context, br blob.Ref, source io.Reader) (blob.SizedRef, error) {
+	size, err := s.conn.ObjectPut(s.container, s.objectName(br), source, true, "", "", nil)
+	if err != nil {
+		return blob.SizedRef{}, err
+	}
+	return blob.SizedRef{Ref: br, Size: uint32(size)}, nil
+}
+
+func (s *storage) RemoveBlobs(ctx context.Context, blobs []blob.Ref) error {
+	for _, br := range blobs {
+		if err := s.conn.ObjectDelete(s.container, s.objectName(br)); err != nil && err != swift.ObjectNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *storage) EnumerateBlobs(ctx context.Context, dest chan<- blob.SizedRef, after string, limit int) error {
+	defer close(dest)
+	opts := &swift.ObjectsOpts{
+		Prefix: s.dirPrefix,
+		Marker: s.dirPrefix + after,
+		Limit:  limit,
+	}
+	objs, err := s.conn.Objects(s.container, opts)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		name := strings.TrimPrefix(obj.Name, s.dirPrefix)
+		br, ok := blob.Parse(name)
+		if !ok {
+			continue
+		}
+		select {
+		case dest <- blob.SizedRef{Ref: br, Size: uint32(obj.Bytes)}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/schema"
+)
+
+// ShareClaim describes one share claim signed by the logged-in user, as
+// returned by ListShares.
+type ShareClaim struct {
+	// ClaimRef is the blobRef of the signed share claim.
+	ClaimRef blob.Ref
+	// Target is the blobRef of the shared file, dir, or permanode.
+	Target blob.Ref
+	// Created is the time at which the claim was signed.
+	Created time.Time
+	// Expires is the RFC3339 time at which the claim stops being valid, or
+	// the empty string if the share never expires.
+	Expires string
+	// Clicks is the number of times the share has been served, or -1 if
+	// the server's listing does not report it. The indexer corpus (see
+	// index.Corpus.ForeachShareClaim) does not track clicks, so the
+	// server's "camli/search/shares" endpoint never reports them.
+	Clicks int
+}
+
+// shareClaimsResponse is the JSON response shape of the "camli/search/shares"
+// endpoint that ListShares queries.
+type shareClaimsResponse struct {
+	Shares []struct {
+		ClaimRef string `json:"claimRef"`
+		Target   string `json:"target"`
+		Created  string `json:"created"`
+		Expires  string `json:"expires"`
+		// Clicks is a pointer so parseShareClaimsResponse can tell an
+		// absent "clicks" field (a server that doesn't track clicks)
+		// apart from a present, zero value (never clicked).
+		Clicks *int `json:"clicks"`
+	} `json:"shares"`
+}
+
+// ListShares returns the share claims that the logged-in user has signed,
+// as tracked by the server's indexer corpus. Claims revoked by a delete
+// claim are left out by the server (see index.Corpus.ForeachShareClaim).
+func (c *Client) ListShares(ctx context.Context) ([]ShareClaim, error) {
+	searchRoot, err := c.SearchRoot()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("GET", searchRoot+"camli/search/shares", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("client: share listing failed: %v", res.Status)
+	}
+	return parseShareClaimsResponse(res.Body)
+}
+
+// parseShareClaimsResponse decodes the body of a "camli/search/shares"
+// response into ShareClaim values, skipping any entry with an unparsable
+// blobRef rather than failing the whole listing.
+func parseShareClaimsResponse(body io.Reader) ([]ShareClaim, error) {
+	var resp shareClaimsResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("client: could not decode share listing: %v", err)
+	}
+	shares := make([]ShareClaim, 0, len(resp.Shares))
+	for _, s := range resp.Shares {
+		claimRef, ok := blob.Parse(s.ClaimRef)
+		if !ok {
+			continue
+		}
+		target, ok := blob.Parse(s.Target)
+		if !ok {
+			continue
+		}
+		created, _ := time.Parse(time.RFC3339, s.Created)
+		clicks := -1
+		if s.Clicks != nil {
+			clicks = *s.Clicks
+		}
+		shares = append(shares, ShareClaim{
+			ClaimRef: claimRef,
+			Target:   target,
+			Created:  created,
+			Expires:  s.Expires,
+			Clicks:   clicks,
+		})
+	}
+	return shares, nil
+}
+
+// RevokeShare signs and uploads a delete claim for claimRef, so the share
+// handler stops honoring it. claimRef is the blobRef string of a
+// previously signed share claim, as returned by ListShares.
+func (c *Client) RevokeShare(ctx context.Context, claimRef string) error {
+	ref, ok := blob.Parse(claimRef)
+	if !ok {
+		return fmt.Errorf("client: invalid share claim ref %q", claimRef)
+	}
+	signer, err := c.ServerPublicKeyBlobRef()
+	if err != nil {
+		return fmt.Errorf("client: could not get signer: %v", err)
+	}
+	unsignedDelete, err := schema.NewDeleteClaim(ref).SetSigner(signer).JSON()
+	if err != nil {
+		return fmt.Errorf("client: could not create unsigned revoke claim: %v", err)
+	}
+	signedDelete, err := c.Sign(ctx, "", strings.NewReader("json="+unsignedDelete))
+	if err != nil {
+		return fmt.Errorf("client: could not sign revoke claim: %v", err)
+	}
+	if _, err := c.Upload(ctx, NewUploadHandleFromString(string(signedDelete))); err != nil {
+		return fmt.Errorf("client: could not upload revoke claim: %v", err)
+	}
+	return nil
+}
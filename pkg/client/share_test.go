@@ -0,0 +1,66 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseShareClaimsResponse(t *testing.T) {
+	const body = `{
+		"shares": [
+			{"claimRef": "sha224-0000000000000000000000000000000000000000000000000000000a", "target": "sha224-0000000000000000000000000000000000000000000000000000000b", "created": "2026-01-02T15:04:05Z", "expires": "2026-02-02T15:04:05Z", "clicks": 3},
+			{"claimRef": "not-a-blobref", "target": "sha224-0000000000000000000000000000000000000000000000000000000b", "created": "2026-01-02T15:04:05Z"}
+		]
+	}`
+	shares, err := parseShareClaimsResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 1 {
+		t.Fatalf("got %d shares, want 1 (the malformed entry should be skipped)", len(shares))
+	}
+	got := shares[0]
+	if got.ClaimRef.String() != "sha224-0000000000000000000000000000000000000000000000000000000a" {
+		t.Errorf("ClaimRef = %v", got.ClaimRef)
+	}
+	if got.Clicks != 3 {
+		t.Errorf("Clicks = %d, want 3", got.Clicks)
+	}
+	if got.Expires != "2026-02-02T15:04:05Z" {
+		t.Errorf("Expires = %q", got.Expires)
+	}
+}
+
+func TestParseShareClaimsResponseClicksUnknown(t *testing.T) {
+	const body = `{
+		"shares": [
+			{"claimRef": "sha224-0000000000000000000000000000000000000000000000000000000a", "target": "sha224-0000000000000000000000000000000000000000000000000000000b", "created": "2026-01-02T15:04:05Z"}
+		]
+	}`
+	shares, err := parseShareClaimsResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 1 {
+		t.Fatalf("got %d shares, want 1", len(shares))
+	}
+	if got := shares[0].Clicks; got != -1 {
+		t.Errorf("Clicks = %d, want -1 for a response with no \"clicks\" field", got)
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package index
+
+import (
+	"time"
+
+	"perkeep.org/pkg/blob"
+)
+
+// ShareClaim is the corpus's view of a "share" claim: enough for the
+// "camli/search/shares" endpoint to answer client.ListShares without
+// re-fetching and re-parsing every claim blob signed by the requester.
+type ShareClaim struct {
+	ClaimRef blob.Ref
+	Target   blob.Ref
+	Created  time.Time
+	Expires  string
+}
+
+// ForeachShareClaim calls fn for every live (i.e. not since deleted) "share"
+// claim signed by signer, most-recently-created first. It stops early if fn
+// returns false.
+func (c *Corpus) ForeachShareClaim(signer blob.Ref, fn func(ShareClaim) bool) {
+	c.ForeachClaim(signer, func(cl *Claim) bool {
+		if cl.Type != "share" || c.IsDeleted(cl.BlobRef) {
+			return true
+		}
+		share := ShareClaim{
+			ClaimRef: cl.BlobRef,
+			Target:   cl.Target,
+			Created:  cl.Date,
+		}
+		if exp, ok := cl.Attr("expires"); ok {
+			share.Expires = exp
+		}
+		return fn(share)
+	})
+}
+
+// PermanodeCurrentContentRef returns the blobRef currently named by
+// permanode's "camliContent" attribute, for resolving a "search"-authType
+// share claim's real target (see schema.ShareSearch): such a claim shares a
+// permanode, and the permanode's content can change over time as new
+// camliContent claims are signed, so the share handler must resolve it
+// through the index rather than through a fixed blobRef in the claim
+// itself. ok is false if permanode has no (live) camliContent attribute.
+func (c *Corpus) PermanodeCurrentContentRef(permanode blob.Ref) (content blob.Ref, ok bool) {
+	return c.PermanodeAttrValue(permanode, "camliContent")
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package qr renders QR codes for short pieces of text, such as share URLs,
+// so that they can be scanned with a phone instead of copy-pasted.
+package qr // import "perkeep.org/pkg/qr"
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image/png"
+
+	"rsc.io/qr"
+)
+
+// Encode returns the PNG encoding of a QR code for text.
+func Encode(text string) ([]byte, error) {
+	code, err := qr.Encode(text, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode QR code: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image()); err != nil {
+		return nil, fmt.Errorf("could not encode QR code as PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DataURI returns a QR code for text, encoded as a "data:image/png;base64,..."
+// URI suitable for an <img> src attribute.
+func DataURI(text string) (string, error) {
+	png, err := Encode(text)
+	if err != nil {
+		return "", err
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png), nil
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qr
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestEncode(t *testing.T) {
+	const text = "https://example.com/share/sha224-0000000000000000000000000000000000000000000000000000000a"
+	png1, err := Encode(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(png1))
+	if err != nil {
+		t.Fatalf("Encode did not produce a decodable PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() == 0 || b.Dy() == 0 {
+		t.Errorf("decoded image has empty bounds %v", b)
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	const text = "https://example.com/share/sha224-0000000000000000000000000000000000000000000000000000000a"
+	uri, err := DataURI(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const prefix = "data:image/png;base64,"
+	if !strings.HasPrefix(uri, prefix) {
+		t.Fatalf("DataURI = %q, want prefix %q", uri, prefix)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import "time"
+
+// ShareAuthBcrypt identifies a share claim's authDigest as a bcrypt hash of
+// the passphrase a viewer must supply, as produced by
+// golang.org/x/crypto/bcrypt.GenerateFromPassword.
+const ShareAuthBcrypt = "bcrypt"
+
+// ShareSearch identifies a share claim whose target is reached through the
+// indexer rather than through static blob references: the share handler
+// resolves the target via search (e.g. a permanode's current camliContent)
+// instead of walking the blobs the target directly embeds. This is what
+// newShareClaim in sharebutton.go uses for permanode targets, since a
+// permanode's content is a mutable, claim-derived relationship that a
+// ShareHaveRef claim's transitive blob walk cannot see.
+const ShareSearch = "search"
+
+// SetShareExpiration sets the time after which the share handler must
+// reject this claim, encoded as the claim's "expires" field in RFC 3339. A
+// Builder that never calls SetShareExpiration produces a claim that never
+// expires.
+func (b *Builder) SetShareExpiration(t time.Time) *Builder {
+	return b.setRawStringField("expires", t.UTC().Format(time.RFC3339))
+}
+
+// SetShareAuthDigest gates the claim behind a passphrase: the share handler
+// must refuse to serve the target until the viewer supplies a passphrase
+// that hashes, per scheme (e.g. ShareAuthBcrypt), to digest. digest is
+// never the plaintext passphrase.
+func (b *Builder) SetShareAuthDigest(scheme, digest string) *Builder {
+	b.setRawStringField("authDigestType", scheme)
+	return b.setRawStringField("authDigest", digest)
+}
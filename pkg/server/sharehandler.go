@@ -0,0 +1,277 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/blobserver"
+	"perkeep.org/pkg/index"
+	"perkeep.org/pkg/schema"
+)
+
+// shareClaim is the subset of a "share" claim's JSON fields that
+// ShareHandler.ServeShare needs, once the claim's signature has been
+// verified, to decide whether a request may proceed and what to serve:
+// whether the claim has expired, whether it's gated behind a passphrase,
+// and how to resolve its target.
+type shareClaim struct {
+	// Target is the blobRef the claim names directly: the shared file or
+	// dir for a ShareHaveRef claim, or the shared permanode for a
+	// ShareSearch claim (see AuthType and ResolveTarget).
+	Target         string `json:"target,omitempty"`
+	AuthType       string `json:"authType,omitempty"`
+	Expires        string `json:"expires,omitempty"`
+	AuthDigestType string `json:"authDigestType,omitempty"`
+	AuthDigest     string `json:"authDigest,omitempty"`
+}
+
+// ShareHandler serves the content of a share claim's target to anyone
+// holding the claim's blobRef, after checking the claim hasn't expired and,
+// if it's passphrase-gated, that the viewer has supplied the passphrase.
+// ShareHandler does not stream the target blob itself (following "?via="
+// chains, assembling directories, etc.): ServeShare only returns the
+// resolved target to its caller, and nothing in this package calls
+// ServeShare.
+type ShareHandler struct {
+	// Fetcher is where share claim blobs are read from.
+	Fetcher blobserver.Fetcher
+	// Index resolves a ShareSearch claim's permanode target to its
+	// current camliContent, and tells ServeShare whether a claim has been
+	// revoked by a delete claim. It may be left nil on a ShareHandler that
+	// only ever serves ShareHaveRef claims; revocation is then not checked.
+	Index *index.Corpus
+
+	mu       sync.Mutex
+	sessions map[string]shareSession // session token -> session
+}
+
+// shareSession records that a viewer has already supplied the correct
+// passphrase for a claim, so checkShareAuth doesn't need to ask again until
+// it expires.
+type shareSession struct {
+	claimRef blob.Ref
+	expires  time.Time
+}
+
+// ServeShare is the share-serving entry point: it fetches and decodes the
+// claim at the blobRef named by req.URL.Path, rejects it if revoked (when
+// sh.Index is set), expired, or left unauthenticated behind a passphrase
+// gate, resolves it to the blobRef it actually grants access to (see
+// ResolveTarget), and returns that target along with the ok=true that lets
+// the caller proceed to stream it.
+func (sh *ShareHandler) ServeShare(rw http.ResponseWriter, req *http.Request) (target blob.Ref, ok bool) {
+	claimRef, ok := blob.Parse(path.Base(req.URL.Path))
+	if !ok {
+		http.Error(rw, "malformed share URL", http.StatusBadRequest)
+		return blob.Ref{}, false
+	}
+	if sh.Index != nil && sh.Index.IsDeleted(claimRef) {
+		http.Error(rw, "share has been revoked", http.StatusGone)
+		return blob.Ref{}, false
+	}
+	rc, _, err := sh.Fetcher.Fetch(req.Context(), claimRef)
+	if err != nil {
+		http.Error(rw, "share not found", http.StatusNotFound)
+		return blob.Ref{}, false
+	}
+	defer rc.Close()
+	var claim shareClaim
+	if err := json.NewDecoder(rc).Decode(&claim); err != nil {
+		http.Error(rw, "malformed share claim", http.StatusInternalServerError)
+		return blob.Ref{}, false
+	}
+	if checkShareExpired(claim) {
+		http.Error(rw, "share has expired", http.StatusGone)
+		return blob.Ref{}, false
+	}
+	if !sh.checkShareAuth(rw, req, claimRef, claim) {
+		return blob.Ref{}, false
+	}
+	target, err = sh.ResolveTarget(claim)
+	if err != nil {
+		http.Error(rw, "could not resolve share target", http.StatusInternalServerError)
+		return blob.Ref{}, false
+	}
+	return target, true
+}
+
+// ResolveTarget returns the blobRef that claim actually grants access to.
+// For a ShareHaveRef claim (the default, used for files and dirs) that's
+// just claim.Target itself. For a ShareSearch claim (used for permanodes,
+// whose content is a mutable, claim-derived relationship rather than a
+// fixed blobRef) it's claim.Target's permanode resolved through sh.Index
+// to its current camliContent, so that editing the permanode's content
+// after the share was created is reflected in what gets served.
+func (sh *ShareHandler) ResolveTarget(claim shareClaim) (blob.Ref, error) {
+	target, ok := blob.Parse(claim.Target)
+	if !ok {
+		return blob.Ref{}, fmt.Errorf("share: malformed target %q", claim.Target)
+	}
+	if claim.AuthType != schema.ShareSearch {
+		return target, nil
+	}
+	if sh.Index == nil {
+		return blob.Ref{}, fmt.Errorf("share: claim targets permanode %v via search, but this handler has no Index to resolve it", target)
+	}
+	content, ok := sh.Index.PermanodeCurrentContentRef(target)
+	if !ok {
+		return blob.Ref{}, fmt.Errorf("share: permanode %v has no current camliContent", target)
+	}
+	return content, nil
+}
+
+// checkShareExpired reports whether claim has an "expires" field that is in
+// the past.
+func checkShareExpired(claim shareClaim) bool {
+	if claim.Expires == "" {
+		return false
+	}
+	t, err := time.Parse(time.RFC3339, claim.Expires)
+	if err != nil {
+		// An unparsable expiration is treated as expired rather than
+		// ignored, so a corrupt or tampered claim fails closed.
+		return true
+	}
+	return time.Now().After(t)
+}
+
+// shareAuthCookiePrefix namespaces the cookie ServeShare sets after a
+// viewer supplies the correct passphrase for a passphrase-gated share, so
+// it can't collide with any other cookie this server sets.
+const shareAuthCookiePrefix = "camli-share-auth-"
+
+// shareSessionTTL is how long a passphrase-gated share's viewer session
+// stays valid once issued. It is intentionally short: unlike the share
+// claim itself, which may be valid for a long time, a cookie stolen off a
+// shared machine shouldn't keep working indefinitely.
+const shareSessionTTL = 30 * time.Minute
+
+// checkShareAuth enforces claim's passphrase gate, if any, for the share
+// identified by claimRef. It reports whether the caller may proceed to
+// resolve and serve claim's target: either claim isn't passphrase gated,
+// the viewer already holds a valid session cookie for claimRef, or this
+// request is the POST of the correct passphrase, in which case
+// checkShareAuth also starts that session and sets its cookie before
+// returning true. If it returns false, it has already written a login form
+// (or an error) to rw and the caller must not write anything else.
+//
+// The session cookie's value is a random token chosen by sh, not the
+// claim's authDigest: the claim blob (authDigest included) is exactly what
+// a share's URL exposes to any viewer, so a cookie built from authDigest
+// itself could be forged by anyone who fetched the claim, without ever
+// supplying the passphrase.
+func (sh *ShareHandler) checkShareAuth(rw http.ResponseWriter, req *http.Request, claimRef blob.Ref, claim shareClaim) bool {
+	if claim.AuthDigest == "" {
+		return true
+	}
+	cookieName := shareAuthCookiePrefix + claimRef.String()
+	if c, err := req.Cookie(cookieName); err == nil && sh.validSession(c.Value, claimRef) {
+		return true
+	}
+	if req.Method == "POST" {
+		switch claim.AuthDigestType {
+		case schema.ShareAuthBcrypt:
+			passphrase := req.PostFormValue("passphrase")
+			if err := bcrypt.CompareHashAndPassword([]byte(claim.AuthDigest), []byte(passphrase)); err == nil {
+				token, err := sh.startSession(claimRef)
+				if err != nil {
+					http.Error(rw, "could not start share session", http.StatusInternalServerError)
+					return false
+				}
+				http.SetCookie(rw, &http.Cookie{
+					Name:     cookieName,
+					Value:    token,
+					Path:     req.URL.Path,
+					Expires:  time.Now().Add(shareSessionTTL),
+					HttpOnly: true,
+				})
+				return true
+			}
+		}
+	}
+	serveShareLoginForm(rw, req)
+	return false
+}
+
+// startSession mints a fresh, unguessable session token for claimRef and
+// records it until it expires, returning the token to set as a cookie
+// value.
+func (sh *ShareHandler) startSession(claimRef blob.Ref) (token string, err error) {
+	var raw [32]byte
+	if _, err := rand.Read(raw[:]); err != nil {
+		return "", fmt.Errorf("could not generate share session token: %v", err)
+	}
+	token = hex.EncodeToString(raw[:])
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if sh.sessions == nil {
+		sh.sessions = make(map[string]shareSession)
+	}
+	sh.sessions[token] = shareSession{
+		claimRef: claimRef,
+		expires:  time.Now().Add(shareSessionTTL),
+	}
+	return token, nil
+}
+
+// validSession reports whether token is a live, unexpired session
+// previously started for claimRef. Expired sessions are evicted as they're
+// encountered, rather than by a separate sweep.
+func (sh *ShareHandler) validSession(token string, claimRef blob.Ref) bool {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	s, ok := sh.sessions[token]
+	if !ok {
+		return false
+	}
+	if time.Now().After(s.expires) {
+		delete(sh.sessions, token)
+		return false
+	}
+	return s.claimRef == claimRef
+}
+
+// serveShareLoginForm writes a minimal HTML form prompting for the
+// passphrase that gates the share being requested at req.URL. Submitting it
+// re-requests the same URL as a POST, which checkShareAuth then validates.
+// req.URL.String() is untrusted (it's echoed straight from the request) and
+// is HTML-escaped before being interpolated into the form's action
+// attribute to avoid a reflected-XSS vector.
+func serveShareLoginForm(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusUnauthorized)
+	fmt.Fprintf(rw, `<!DOCTYPE html>
+<html><body>
+<form method="POST" action="%s">
+<p>This share is protected by a passphrase.</p>
+<input type="password" name="passphrase" autofocus>
+<input type="submit" value="View">
+</form>
+</body></html>`, html.EscapeString(req.URL.String()))
+}
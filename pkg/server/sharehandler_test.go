@@ -0,0 +1,187 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/schema"
+)
+
+func TestCheckShareExpired(t *testing.T) {
+	tests := []struct {
+		name    string
+		expires string
+		want    bool
+	}{
+		{"no expiration", "", false},
+		{"future", time.Now().Add(time.Hour).UTC().Format(time.RFC3339), false},
+		{"past", time.Now().Add(-time.Hour).UTC().Format(time.RFC3339), true},
+		{"unparsable fails closed", "not-a-time", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkShareExpired(shareClaim{Expires: tt.expires})
+			if got != tt.want {
+				t.Errorf("checkShareExpired(%q) = %v, want %v", tt.expires, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckShareAuth(t *testing.T) {
+	claimRef, ok := blob.Parse("sha224-0000000000000000000000000000000000000000000000000000000a")
+	if !ok {
+		t.Fatal("could not parse test blobRef")
+	}
+	digest, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim := shareClaim{AuthDigestType: schema.ShareAuthBcrypt, AuthDigest: string(digest)}
+
+	t.Run("ungated claim always passes", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/share/"+claimRef.String(), nil)
+		if ok := sh.checkShareAuth(rw, req, claimRef, shareClaim{}); !ok {
+			t.Errorf("checkShareAuth returned false for an ungated claim")
+		}
+	})
+
+	t.Run("unauthenticated GET serves the login form", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/share/"+claimRef.String(), nil)
+		if ok := sh.checkShareAuth(rw, req, claimRef, claim); ok {
+			t.Errorf("checkShareAuth returned true without a passphrase")
+		}
+		if rw.Code != 401 {
+			t.Errorf("status = %d, want 401", rw.Code)
+		}
+	})
+
+	t.Run("login form escapes the request URL", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/share/"+claimRef.String()+`?via="><script>alert(1)</script>`, nil)
+		sh.checkShareAuth(rw, req, claimRef, claim)
+		if body := rw.Body.String(); strings.Contains(body, "<script>") {
+			t.Errorf("login form echoed an unescaped <script> tag:\n%s", body)
+		}
+	})
+
+	t.Run("correct passphrase sets an opaque session cookie and passes", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		form := url.Values{"passphrase": {"hunter2"}}
+		req := httptest.NewRequest("POST", "/share/"+claimRef.String(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if ok := sh.checkShareAuth(rw, req, claimRef, claim); !ok {
+			t.Fatal("checkShareAuth returned false for the correct passphrase")
+		}
+		cookies := rw.Result().Cookies()
+		if len(cookies) == 0 {
+			t.Fatal("no cookie was set after a successful passphrase check")
+		}
+		if cookies[0].Value == string(digest) {
+			t.Errorf("session cookie value is the bcrypt digest itself, which is forgeable by anyone who reads the public claim")
+		}
+	})
+
+	t.Run("wrong passphrase fails", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		form := url.Values{"passphrase": {"wrong"}}
+		req := httptest.NewRequest("POST", "/share/"+claimRef.String(), strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if ok := sh.checkShareAuth(rw, req, claimRef, claim); ok {
+			t.Errorf("checkShareAuth returned true for a wrong passphrase")
+		}
+	})
+
+	t.Run("a forged cookie carrying the claim's own digest is rejected", func(t *testing.T) {
+		sh := &ShareHandler{}
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/share/"+claimRef.String(), nil)
+		req.AddCookie(&http.Cookie{
+			Name:  shareAuthCookiePrefix + claimRef.String(),
+			Value: string(digest),
+		})
+		if ok := sh.checkShareAuth(rw, req, claimRef, claim); ok {
+			t.Errorf("checkShareAuth accepted a cookie built from the claim's public authDigest")
+		}
+	})
+
+	t.Run("valid session token passes without re-checking the passphrase", func(t *testing.T) {
+		sh := &ShareHandler{}
+		token, err := sh.startSession(claimRef)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/share/"+claimRef.String(), nil)
+		req.AddCookie(&http.Cookie{
+			Name:  shareAuthCookiePrefix + claimRef.String(),
+			Value: token,
+		})
+		if ok := sh.checkShareAuth(rw, req, claimRef, claim); !ok {
+			t.Errorf("checkShareAuth returned false with a valid session token")
+		}
+	})
+}
+
+func TestResolveTarget(t *testing.T) {
+	targetStr := "sha224-0000000000000000000000000000000000000000000000000000000c"
+	target, ok := blob.Parse(targetStr)
+	if !ok {
+		t.Fatal("could not parse test blobRef")
+	}
+
+	t.Run("ShareHaveRef resolves to its target directly", func(t *testing.T) {
+		sh := &ShareHandler{}
+		got, err := sh.ResolveTarget(shareClaim{Target: targetStr})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != target {
+			t.Errorf("ResolveTarget = %v, want %v", got, target)
+		}
+	})
+
+	t.Run("ShareSearch without an Index fails", func(t *testing.T) {
+		sh := &ShareHandler{}
+		if _, err := sh.ResolveTarget(shareClaim{Target: targetStr, AuthType: schema.ShareSearch}); err == nil {
+			t.Error("expected an error resolving a search claim with no Index")
+		}
+	})
+
+	t.Run("malformed target fails", func(t *testing.T) {
+		sh := &ShareHandler{}
+		if _, err := sh.ResolveTarget(shareClaim{Target: "not-a-blobref"}); err == nil {
+			t.Error("expected an error for a malformed target")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"perkeep.org/pkg/blob"
+	"perkeep.org/pkg/index"
+)
+
+// SharesSearchHandler serves the "camli/search/shares" endpoint that
+// client.ListShares queries: the live "share" claims signed by Owner, as
+// tracked by Corpus.
+type SharesSearchHandler struct {
+	// Corpus is the indexer's in-memory view of all claims.
+	Corpus *index.Corpus
+	// Owner is the signer whose share claims are listed; only the
+	// logged-in user's own shares are ever returned.
+	Owner blob.Ref
+}
+
+// shareListEntry is the JSON shape of one entry in sharesSearchResponse,
+// matching what client.parseShareClaimsResponse expects. There's no
+// "clicks" field: this corpus doesn't track how many times a share has
+// been served, so it's left out of the response entirely rather than
+// reported as zero, letting the client tell "never clicked" apart from
+// "not tracked".
+type shareListEntry struct {
+	ClaimRef string `json:"claimRef"`
+	Target   string `json:"target"`
+	Created  string `json:"created"`
+	Expires  string `json:"expires,omitempty"`
+}
+
+type sharesSearchResponse struct {
+	Shares []shareListEntry `json:"shares"`
+}
+
+func (sh *SharesSearchHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(rw, "GET only", http.StatusMethodNotAllowed)
+		return
+	}
+	var resp sharesSearchResponse
+	sh.Corpus.ForeachShareClaim(sh.Owner, func(s index.ShareClaim) bool {
+		resp.Shares = append(resp.Shares, shareListEntry{
+			ClaimRef: s.ClaimRef.String(),
+			Target:   s.Target.String(),
+			Created:  s.Created.UTC().Format("2006-01-02T15:04:05Z07:00"),
+			Expires:  s.Expires,
+		})
+		return true
+	})
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(resp); err != nil {
+		http.Error(rw, "could not encode share listing", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serverinit generates the low-level server configuration that
+// Perkeep's components are configured from,
+// out of the simpler, high-level serverconfig.Config that users write by
+// hand.
+package serverinit // import "perkeep.org/pkg/serverinit"
+
+import (
+	"fmt"
+	"strings"
+
+	"perkeep.org/pkg/jsonconfig"
+	"perkeep.org/pkg/types/serverconfig"
+)
+
+// genLowLevelConfig turns conf into the low-level configuration consumed by
+// the rest of the server: a "prefixes" map from URL path prefix to the
+// handler (and its args) mounted there.
+//
+// It only builds the "/bs/" (blob storage) prefix; it does not generate the
+// identity, indexing, UI, or app-handler prefixes.
+func genLowLevelConfig(conf *serverconfig.Config) (jsonconfig.Obj, error) {
+	handlerType, handlerArgs, err := blobStorageHandler(conf)
+	if err != nil {
+		return nil, err
+	}
+	prefixes := jsonconfig.Obj{
+		"/bs/": jsonconfig.Obj{
+			"handler":     handlerType,
+			"handlerArgs": handlerArgs,
+		},
+	}
+	return jsonconfig.Obj{"prefixes": prefixes}, nil
+}
+
+// blobStorageHandler returns the handler type and args for conf's chosen
+// blob storage backend. Exactly one of MemoryStorage, BlobPath, S3, B2,
+// GoogleCloudStorage, GoogleDrive, Swift, or Azure is expected to be set;
+// if conf.PackRelated is set, the result is wrapped in "storage-blobpacked"
+// so that packed ("blobpacked") and loose blobs land in the same
+// destination regardless of backend.
+func blobStorageHandler(conf *serverconfig.Config) (handlerType string, handlerArgs jsonconfig.Obj, err error) {
+	switch {
+	case conf.MemoryStorage:
+		handlerType, handlerArgs = "storage-memory", jsonconfig.Obj{}
+	case conf.BlobPath != "":
+		if conf.PackBlobs {
+			handlerType = "storage-diskpacked"
+		} else {
+			handlerType = "storage-filesystem"
+		}
+		handlerArgs = jsonconfig.Obj{"path": conf.BlobPath}
+	case conf.S3 != "":
+		handlerType = "storage-s3"
+		handlerArgs, err = s3HandlerArgs(conf.S3)
+	case conf.B2 != "":
+		handlerType = "storage-b2"
+		handlerArgs, err = b2HandlerArgs(conf.B2)
+	case conf.GoogleCloudStorage != "":
+		handlerType = "storage-googlecloudstorage"
+		handlerArgs, err = googleCloudStorageHandlerArgs(conf.GoogleCloudStorage)
+	case conf.GoogleDrive != "":
+		handlerType = "storage-googledrive"
+		handlerArgs, err = googleDriveHandlerArgs(conf.GoogleDrive)
+	case conf.Swift != "" || conf.Azure != "":
+		handlerType, handlerArgs, err = addSwiftOrAzureStorage(conf.Swift, conf.Azure)
+	default:
+		return "", nil, fmt.Errorf("serverinit: no blob storage backend configured")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if !conf.PackRelated {
+		return handlerType, handlerArgs, nil
+	}
+	return "storage-blobpacked", jsonconfig.Obj{
+		"smallBlobs": jsonconfig.Obj{
+			"handler":     handlerType,
+			"handlerArgs": handlerArgs,
+		},
+		"largeBlobs": jsonconfig.Obj{
+			"handler":     handlerType,
+			"handlerArgs": handlerArgs,
+		},
+	}, nil
+}
+
+// s3HandlerArgs parses conf.S3 (as documented on serverconfig.Config.S3:
+// "access_key_id:secret_access_key:bucket[/optional/dir][:hostname]") into
+// the handlerArgs expected by blobserver/s3's "s3" storage type.
+func s3HandlerArgs(s3 string) (jsonconfig.Obj, error) {
+	fields := strings.Split(s3, ":")
+	if len(fields) < 3 {
+		return nil, fmt.Errorf(`serverinit: expected "s3" to be of the form ` +
+			`"access_key_id:secret_access_key:bucket[/optional/dir][:hostname]"`)
+	}
+	args := jsonconfig.Obj{
+		"aws_access_key":        fields[0],
+		"aws_secret_access_key": fields[1],
+		"bucket":                fields[2],
+	}
+	if len(fields) > 3 && fields[3] != "" {
+		args["hostname"] = fields[3]
+	}
+	return args, nil
+}
+
+// b2HandlerArgs parses conf.B2 (as documented on serverconfig.Config.B2:
+// "account_id:application_key:bucket[/optional/dir]") into the handlerArgs
+// expected by blobserver/b2's "b2" storage type.
+func b2HandlerArgs(b2 string) (jsonconfig.Obj, error) {
+	fields := strings.Split(b2, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf(`serverinit: expected "b2" to be of the form ` +
+			`"account_id:application_key:bucket[/optional/dir]"`)
+	}
+	return jsonconfig.Obj{
+		"account_id":      fields[0],
+		"application_key": fields[1],
+		"bucket":          fields[2],
+	}, nil
+}
+
+// googleCloudStorageHandlerArgs parses conf.GoogleCloudStorage (as
+// documented on serverconfig.Config.GoogleCloudStorage:
+// "clientId:clientSecret:refreshToken:bucket[/optional/dir]", or
+// ":bucket[/optional/dir/]" to use the GCE instance's own credentials) into
+// the handlerArgs expected by blobserver/google/cloudstorage's
+// "googlecloudstorage" storage type.
+func googleCloudStorageHandlerArgs(gcs string) (jsonconfig.Obj, error) {
+	fields := strings.Split(gcs, ":")
+	if len(fields) == 2 && fields[0] == "" {
+		return jsonconfig.Obj{"bucket": fields[1]}, nil
+	}
+	if len(fields) != 4 {
+		return nil, fmt.Errorf(`serverinit: expected "googlecloudstorage" to be of the form ` +
+			`"clientId:clientSecret:refreshToken:bucket[/optional/dir]" or ":bucket[/optional/dir]"`)
+	}
+	return jsonconfig.Obj{
+		"client_id":     fields[0],
+		"client_secret": fields[1],
+		"refresh_token": fields[2],
+		"bucket":        fields[3],
+	}, nil
+}
+
+// googleDriveHandlerArgs parses conf.GoogleDrive (as documented on
+// serverconfig.Config.GoogleDrive: "clientId:clientSecret:refreshToken:parentId")
+// into the handlerArgs expected by blobserver/google/drive's "googledrive"
+// storage type.
+func googleDriveHandlerArgs(drive string) (jsonconfig.Obj, error) {
+	fields := strings.Split(drive, ":")
+	if len(fields) != 4 {
+		return nil, fmt.Errorf(`serverinit: expected "googledrive" to be of the form ` +
+			`"clientId:clientSecret:refreshToken:parentId"`)
+	}
+	return jsonconfig.Obj{
+		"client_id":     fields[0],
+		"client_secret": fields[1],
+		"refresh_token": fields[2],
+		"parent_id":     fields[3],
+	}, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverinit
+
+import (
+	"testing"
+
+	"perkeep.org/pkg/jsonconfig"
+	"perkeep.org/pkg/types/serverconfig"
+)
+
+func TestBlobStorageHandlerSwiftAndAzure(t *testing.T) {
+	t.Run("swift unpacked", func(t *testing.T) {
+		conf := &serverconfig.Config{Swift: "user:key:auth.example.com/v3:bucket"}
+		typ, args, err := blobStorageHandler(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != "storage-swift" {
+			t.Errorf("handler = %q, want storage-swift", typ)
+		}
+		if args["container"] != "bucket" {
+			t.Errorf("container = %v", args["container"])
+		}
+	})
+
+	t.Run("azure wrapped in blobpacked when PackRelated is set", func(t *testing.T) {
+		conf := &serverconfig.Config{Azure: "acct:key:bucket", PackRelated: true}
+		typ, args, err := blobStorageHandler(conf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != "storage-blobpacked" {
+			t.Fatalf("handler = %q, want storage-blobpacked", typ)
+		}
+		small, ok := args["smallBlobs"].(jsonconfig.Obj)
+		if !ok {
+			t.Fatalf("smallBlobs has unexpected type %T", args["smallBlobs"])
+		}
+		if small["handler"] != "storage-azure" {
+			t.Errorf("smallBlobs.handler = %v, want storage-azure", small["handler"])
+		}
+	})
+
+	t.Run("no backend configured errors", func(t *testing.T) {
+		if _, _, err := blobStorageHandler(&serverconfig.Config{}); err == nil {
+			t.Error("expected an error when no blob storage backend is configured")
+		}
+	})
+}
+
+func TestGenLowLevelConfigWiresSwift(t *testing.T) {
+	conf := &serverconfig.Config{Swift: "user:key:auth.example.com/v3:bucket"}
+	low, err := genLowLevelConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixes, ok := low["prefixes"].(jsonconfig.Obj)
+	if !ok {
+		t.Fatalf("prefixes has unexpected type %T", low["prefixes"])
+	}
+	bs, ok := prefixes["/bs/"].(jsonconfig.Obj)
+	if !ok {
+		t.Fatalf("/bs/ has unexpected type %T", prefixes["/bs/"])
+	}
+	if bs["handler"] != "storage-swift" {
+		t.Errorf("/bs/ handler = %v, want storage-swift", bs["handler"])
+	}
+}
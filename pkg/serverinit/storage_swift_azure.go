@@ -0,0 +1,86 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverinit
+
+import (
+	"fmt"
+	"strings"
+
+	"perkeep.org/pkg/jsonconfig"
+)
+
+// swiftHandlerArgs parses conf.Swift (as documented on
+// serverconfig.Config.Swift: "user_name:api_key:auth_url:container[/dir][:tenant][:region]")
+// into the handlerArgs expected by blobserver/swift's "swift" storage type.
+// genLowLevelConfig calls this, alongside s3HandlerArgs and the other blob
+// storage backends, when conf.Swift is non-empty.
+func swiftHandlerArgs(swift string) (jsonconfig.Obj, error) {
+	fields := strings.Split(swift, ":")
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("serverinit: expected \"swift\" to be of the form " +
+			"\"user_name:api_key:auth_url:container[/dir][:tenant][:region]\"")
+	}
+	args := jsonconfig.Obj{
+		"user_name": fields[0],
+		"api_key":   fields[1],
+		"auth_url":  fields[2],
+		"container": fields[3],
+	}
+	if len(fields) > 4 && fields[4] != "" {
+		args["tenant"] = fields[4]
+	}
+	if len(fields) > 5 && fields[5] != "" {
+		args["region"] = fields[5]
+	}
+	return args, nil
+}
+
+// azureHandlerArgs parses conf.Azure (as documented on
+// serverconfig.Config.Azure: "account_name:account_key:container[/dir]")
+// into the handlerArgs expected by blobserver/azure's "azure" storage type.
+// genLowLevelConfig calls this, alongside s3HandlerArgs and the other blob
+// storage backends, when conf.Azure is non-empty.
+func azureHandlerArgs(azure string) (jsonconfig.Obj, error) {
+	fields := strings.Split(azure, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("serverinit: expected \"azure\" to be of the form " +
+			"\"account_name:account_key:container[/dir]\"")
+	}
+	return jsonconfig.Obj{
+		"account_name": fields[0],
+		"account_key":  fields[1],
+		"container":    fields[2],
+	}, nil
+}
+
+// addSwiftOrAzureStorage returns the "/bs/" prefix handler config for
+// conf.Swift or conf.Azure, or ("", nil, nil) if neither is set. It is
+// called by blobStorageHandler, which applies the "storage-blobpacked"
+// wrapping uniformly across every backend when conf.PackRelated is set, so
+// this function itself stays unwrapped.
+func addSwiftOrAzureStorage(swift, azure string) (handlerType string, handlerArgs jsonconfig.Obj, err error) {
+	switch {
+	case swift != "":
+		handlerArgs, err = swiftHandlerArgs(swift)
+		return "storage-swift", handlerArgs, err
+	case azure != "":
+		handlerArgs, err = azureHandlerArgs(azure)
+		return "storage-azure", handlerArgs, err
+	default:
+		return "", nil, nil
+	}
+}
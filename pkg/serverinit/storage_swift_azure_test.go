@@ -0,0 +1,60 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serverinit
+
+import "testing"
+
+func TestAddSwiftOrAzureStorage(t *testing.T) {
+	t.Run("neither set", func(t *testing.T) {
+		typ, args, err := addSwiftOrAzureStorage("", "")
+		if err != nil || typ != "" || args != nil {
+			t.Fatalf("got (%q, %v, %v), want (\"\", nil, nil)", typ, args, err)
+		}
+	})
+
+	t.Run("swift", func(t *testing.T) {
+		typ, args, err := addSwiftOrAzureStorage("user:key:auth.example.com/v3:bucket/dir:tenant:region", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != "storage-swift" {
+			t.Errorf("handler = %q, want storage-swift", typ)
+		}
+		if args["container"] != "bucket/dir" {
+			t.Errorf("container = %v", args["container"])
+		}
+	})
+
+	t.Run("azure", func(t *testing.T) {
+		typ, args, err := addSwiftOrAzureStorage("", "acct:key:bucket")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if typ != "storage-azure" {
+			t.Errorf("handler = %q, want storage-azure", typ)
+		}
+		if args["account_name"] != "acct" {
+			t.Errorf("account_name = %v", args["account_name"])
+		}
+	})
+
+	t.Run("malformed swift string errors", func(t *testing.T) {
+		if _, _, err := addSwiftOrAzureStorage("too:few:fields", ""); err == nil {
+			t.Error("expected an error for a malformed swift config string")
+		}
+	})
+}
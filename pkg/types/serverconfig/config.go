@@ -64,6 +64,8 @@ type Config struct {
 	B2                 string `json:"b2,omitempty"`                 // Backblaze B2 credentials: account_id:application_key:bucket[/optional/dir].
 	GoogleCloudStorage string `json:"googlecloudstorage,omitempty"` // Google Cloud credentials: clientId:clientSecret:refreshToken:bucket[/optional/dir] or ":bucket[/optional/dir/]" for auto on GCE
 	GoogleDrive        string `json:"googledrive,omitempty"`        // Google Drive credentials: clientId:clientSecret:refreshToken:parentId.
+	Swift              string `json:"swift,omitempty"`              // OpenStack Swift credentials: user_name:api_key:auth_url:container[/optional/dir][:tenant][:region]. Wrapped in "storage-blobpacked" like the other remote backends when PackRelated is set.
+	Azure              string `json:"azure,omitempty"`              // Azure Blob Storage credentials: account_name:account_key:container[/optional/dir]. Wrapped in "storage-blobpacked" like the other remote backends when PackRelated is set.
 	ShareHandler       bool   `json:"shareHandler,omitempty"`       // enable the share handler. If true, and shareHandlerPath is empty then shareHandlerPath will default to "/share/" when generating the low-level config.
 	ShareHandlerPath   string `json:"shareHandlerPath,omitempty"`   // URL prefix for the share handler. If set, overrides shareHandler.
 
@@ -28,13 +28,17 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopherjs/gopherjs/js"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 
 	"perkeep.org/pkg/auth"
 	"perkeep.org/pkg/blob"
 	"perkeep.org/pkg/client"
+	"perkeep.org/pkg/qr"
 	"perkeep.org/pkg/schema"
 
 	"honnef.co/go/js/dom"
@@ -111,11 +115,70 @@ type Callbacks struct {
 	// GetSelection returns the list of items selected for sharing.
 	GetSelection func() []SharedItem `js:"getSelection"`
 
+	// GetShareOptions returns the expiry, passphrase, and transitivity that the
+	// user chose in the share options popover. It is optional: a nil value
+	// means a non-expiring, passphrase-less share whose transitivity is picked
+	// by defaultTransitive.
+	GetShareOptions func() ShareOptions `js:"getShareOptions"`
+
 	// ShowSharedURL displays in a dialog an anchor with anchorURL for its
 	// href and anchorText for its text.
 	ShowSharedURL func(anchorURL string, anchorText string) `js:"showSharedURL"`
+
+	// ShowSharedQR, if set, is called right after ShowSharedURL with the same
+	// URL and a "data:image/png;base64,..." encoding of its QR code, so the
+	// dialog can let a phone scan it instead of the URL being copy-pasted.
+	ShowSharedQR func(url string, pngDataURI string) `js:"showSharedQR"`
+
+	// OnShareCreated, if set, is called right after a share claim has been
+	// signed and uploaded, so that the shares management panel (see the
+	// sharespanel package) can list it without waiting for the next refresh of
+	// the indexer corpus. claimRef and target are both blobRef strings.
+	OnShareCreated func(claimRef string, target string) `js:"onShareCreated"`
+
+	// OnUploadProgress, if set, is called as blobs are uploaded while building
+	// the directory for a multi-item share, so the dialog can show a progress
+	// indicator. done and total count static-set subset blobs, not bytes.
+	OnUploadProgress func(done int, total int) `js:"onUploadProgress"`
 }
 
+// ShareOptions holds the expiration, passphrase-gating, and transitivity
+// chosen by the user in the share options popover, before the claim is
+// signed.
+type ShareOptions struct {
+	// Expires is how long the share claim stays valid for, starting from when
+	// it gets signed. A zero value means the share never expires.
+	Expires time.Duration
+
+	// Passphrase, when non-empty, is required from the viewer before the
+	// share handler serves the target. It is never transmitted or stored in
+	// the clear: newShareClaim hashes it into the claim's authDigest.
+	Passphrase string
+
+	// Transitive controls whether the claim grants access to the blobs that
+	// the target references (e.g. a directory's children, or a permanode's
+	// referenced files), rather than just the target blob itself. It is only
+	// honored when it comes from Callbacks.GetShareOptions; when that callback
+	// is nil, shareSelection picks a default itself (see defaultTransitive).
+	Transitive bool
+}
+
+// defaultTransitive reports whether a share of an item of the given kind
+// should be transitive by default, absent explicit user input. Single files
+// never need transitivity to be fetched, so making them transitive by default
+// would only broaden exposure for no benefit.
+func defaultTransitive(kind string) bool {
+	return kind != shareKindFile
+}
+
+// The recognized values for the "type" key of a SharedItem, identifying what
+// kind of blob is being shared.
+const (
+	shareKindFile      = "file"
+	shareKindDir       = "dir"
+	shareKindPermanode = "permanode"
+)
+
 // ShareItemsBtnDef is the definition for the button, that Renders as a React
 // Button.
 type ShareItemsBtnDef struct {
@@ -127,11 +190,34 @@ type ShareItemsBtnDef struct {
 // A SharedItem's expected keys are:
 //   "blobRef": "sha1-foo",
 //   "isDir": "boolString",
-// where "sha1-foo" is the ref of a file or a dir to share.
-// and the value for "isDir", interpreted as a boolean with strconv, reports
-// whether the ref is of a dir.
+//   "type": "file" | "dir" | "permanode",
+// where "sha1-foo" is the ref of the file, dir, or permanode to share.
+// "type" takes precedence over "isDir" when both are present; "isDir" is kept
+// for compatibility with callers that predate permanode sharing, and is
+// interpreted as a boolean with strconv to tell apart "file" from "dir".
 type SharedItem map[string]string
 
+// itemKind returns the share kind ("file", "dir", or "permanode") for item,
+// preferring its "type" key and falling back to the legacy "isDir" key.
+func itemKind(item SharedItem) (string, error) {
+	if kind, ok := item["type"]; ok && kind != "" {
+		switch kind {
+		case shareKindFile, shareKindDir, shareKindPermanode:
+			return kind, nil
+		default:
+			return "", fmt.Errorf("invalid share type %q", kind)
+		}
+	}
+	isDir, err := strconv.ParseBool(item["isDir"])
+	if err != nil {
+		return "", fmt.Errorf("invalid boolean value %q for isDir: %v", item["isDir"], err)
+	}
+	if isDir {
+		return shareKindDir, nil
+	}
+	return shareKindFile, nil
+}
+
 type ShareItemsBtnProps struct {
 	// Key is the id for when the button is in a list, see
 	// https://facebook.github.io/react/docs/lists-and-keys.html
@@ -165,11 +251,23 @@ func (d ShareItemsBtnDef) Render() react.Element {
 // pk-get -shared.
 func (d ShareItemsBtnDef) OnClick(e *react.SyntheticMouseEvent) {
 	go func() {
-		sharedURL, err := d.shareSelection()
+		// ctx is canceled when this goroutine returns. Nothing cancels it
+		// earlier: tearing down this component does not stop an upload still
+		// in flight.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var opts ShareOptions
+		if getOpts := d.Props().callbacks.GetShareOptions; getOpts != nil {
+			opts = getOpts()
+		}
+		sharedURL, claimRef, target, err := d.shareSelection(ctx, opts)
 		if err != nil {
 			dom.GetWindow().Alert(fmt.Sprintf("%v", err))
 			return
 		}
+		if onCreated := d.Props().callbacks.OnShareCreated; onCreated != nil {
+			onCreated(claimRef.String(), target.String())
+		}
 		prefix, err := d.urlPrefix()
 		if err != nil {
 			dom.GetWindow().Alert(fmt.Sprintf("Cannot display full share URL: %v", err))
@@ -179,50 +277,77 @@ func (d ShareItemsBtnDef) OnClick(e *react.SyntheticMouseEvent) {
 		anchorText := sharedURL[:20] + "..." + sharedURL[len(sharedURL)-20:]
 		// TODO(mpl): move some of the Dialog code to Go.
 		d.Props().callbacks.ShowSharedURL(sharedURL, anchorText)
+		if showQR := d.Props().callbacks.ShowSharedQR; showQR != nil {
+			dataURI, err := qr.DataURI(sharedURL)
+			if err != nil {
+				fmt.Println("could not render QR code for shared URL:", err)
+				return
+			}
+			showQR(sharedURL, dataURI)
+		}
 	}()
 }
 
-func (d ShareItemsBtnDef) shareSelection() (string, error) {
+func (d ShareItemsBtnDef) shareSelection(ctx context.Context, opts ShareOptions) (sharedURL string, claimRef blob.Ref, target blob.Ref, err error) {
 	selection := d.Props().callbacks.GetSelection()
 	authToken := d.Props().authToken
 	am, err := auth.NewTokenAuth(authToken)
 	if err != nil {
-		return "", fmt.Errorf("Error setting up auth for share request: %v", err)
+		return "", claimRef, target, fmt.Errorf("Error setting up auth for share request: %v", err)
 	}
 	var fileRef []blob.Ref
-	isDir := false
+	kind := shareKindFile
 	for _, item := range selection {
 		br, ok := item["blobRef"]
 		if !ok {
-			return "", fmt.Errorf("cannot share item, it's missing a blobRef")
+			return "", claimRef, target, fmt.Errorf("cannot share item, it's missing a blobRef")
 		}
 		fbr, ok := blob.Parse(br)
 		if !ok {
-			return "", fmt.Errorf("cannot share %q, not a valid blobRef", br)
+			return "", claimRef, target, fmt.Errorf("cannot share %q, not a valid blobRef", br)
 		}
 		fileRef = append(fileRef, fbr)
-		isDir, err = strconv.ParseBool(item["isDir"])
+		kind, err = itemKind(item)
 		if err != nil {
-			return "", fmt.Errorf("invalid boolean value %q for isDir: %v", item["isDir"], err)
+			return "", claimRef, target, err
+		}
+	}
+	if d.Props().callbacks.GetShareOptions == nil {
+		if len(fileRef) == 1 {
+			opts.Transitive = defaultTransitive(kind)
+		} else {
+			// Several items are shared as one new directory.
+			opts.Transitive = defaultTransitive(shareKindDir)
 		}
 	}
 	if len(fileRef) == 1 {
-		return shareFile(am, fileRef[0], isDir)
+		sharedURL, claimRef, err = shareFile(ctx, am, fileRef[0], kind, opts)
+		return sharedURL, claimRef, fileRef[0], err
 	}
-	newDirbr, err := mkdir(am, fileRef)
+	onProgress := d.Props().callbacks.OnUploadProgress
+	newDirbr, err := mkdir(ctx, am, fileRef, onProgress)
 	if err != nil {
-		return "", fmt.Errorf("failed creating new directory for selected items: %v", err)
+		return "", claimRef, target, fmt.Errorf("failed creating new directory for selected items: %v", err)
 	}
 	// TODO(mpl): should we bother deleting the dir and static set if
 	// there's any failure from this point on? I think that as long as there's
 	// no share claim referencing them, they're supposed to be GCed eventually,
 	// aren't they? in which case, no need to worry about it.
-	return shareFile(am, newDirbr, true)
+	sharedURL, claimRef, err = shareFile(ctx, am, newDirbr, shareKindDir, opts)
+	return sharedURL, claimRef, newDirbr, err
 }
 
+// maxConcurrentUploads bounds how many static-set subset blobs mkdir uploads
+// in parallel.
+const maxConcurrentUploads = 8
+
 // mkdir creates a new directory blob, with children composing its static-set,
-// and uploads it. It returns the blobRef of the new directory.
-func mkdir(am auth.AuthMode, children []blob.Ref) (blob.Ref, error) {
+// and uploads it. It returns the blobRef of the new directory. Subset blobs
+// that the server already has (per Stat) are skipped, and the remaining ones
+// are uploaded concurrently, bounded by maxConcurrentUploads; a failure on any
+// of them cancels the others. If onProgress is non-nil, it is called after
+// each subset blob is accounted for, uploaded or already present.
+func mkdir(ctx context.Context, am auth.AuthMode, children []blob.Ref, onProgress func(done, total int)) (blob.Ref, error) {
 	cl, err := client.New(client.OptionAuthMode(am))
 	if err != nil {
 		return blob.Ref{}, err
@@ -230,68 +355,138 @@ func mkdir(am auth.AuthMode, children []blob.Ref) (blob.Ref, error) {
 	var newdir blob.Ref
 	ss := schema.NewStaticSet()
 	subsets := ss.SetStaticSetMembers(children)
+
+	refs := make([]blob.Ref, len(subsets))
+	for i, v := range subsets {
+		refs[i] = v.BlobRef()
+	}
+	have := make(map[blob.Ref]bool, len(refs))
+	if err := cl.StatBlobs(ctx, refs, func(sb blob.SizedRef) {
+		have[sb.Ref] = true
+	}); err != nil {
+		return newdir, fmt.Errorf("could not stat static-set subsets: %v", err)
+	}
+
+	var done int32
+	total := len(subsets)
+	g, gCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, maxConcurrentUploads)
 	for _, v := range subsets {
-		// TODO(mpl): make them concurrent
-		if _, err := cl.UploadBlob(context.TODO(), v); err != nil {
-			return newdir, err
+		v := v
+		if have[v.BlobRef()] {
+			n := atomic.AddInt32(&done, 1)
+			if onProgress != nil {
+				onProgress(int(n), total)
+			}
+			continue
 		}
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+			if _, err := cl.UploadBlob(gCtx, v); err != nil {
+				return err
+			}
+			n := atomic.AddInt32(&done, 1)
+			if onProgress != nil {
+				onProgress(int(n), total)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return newdir, err
 	}
+
 	ssb := ss.Blob()
-	if _, err := cl.UploadBlob(context.TODO(), ssb); err != nil {
+	if _, err := cl.UploadBlob(ctx, ssb); err != nil {
 		return newdir, err
 	}
 	const fileNameLayout = "20060102150405"
 	fileName := "shared-" + time.Now().Format(fileNameLayout)
 	dir := schema.NewDirMap(fileName).PopulateDirectoryMap(ssb.BlobRef())
 	dirBlob := dir.Blob()
-	if _, err := cl.UploadBlob(context.TODO(), dirBlob); err != nil {
+	if _, err := cl.UploadBlob(ctx, dirBlob); err != nil {
 		return newdir, err
 	}
 
 	return dirBlob.BlobRef(), nil
 }
 
-// shareFile returns the URL that can be used to share the target item. If the
-// item is a file, the URL can be used directly to fetch the file. If the item is a
-// directory, the URL should be used with pk-get -shared.
-func shareFile(am auth.AuthMode, target blob.Ref, isDir bool) (string, error) {
+// shareFile returns the URL that can be used to share the target item, along
+// with the ref of the share claim that was created for it. If kind is
+// shareKindFile, the URL can be used directly to fetch the file. Otherwise
+// (shareKindDir or shareKindPermanode), the URL should be used with
+// pk-get -shared, or browsed directly.
+func shareFile(ctx context.Context, am auth.AuthMode, target blob.Ref, kind string, opts ShareOptions) (string, blob.Ref, error) {
 	cl, err := client.New(client.OptionAuthMode(am))
 	if err != nil {
-		return "", err
+		return "", blob.Ref{}, err
 	}
-	claim, err := newShareClaim(cl, target)
+	claim, err := newShareClaim(ctx, cl, target, kind, opts)
 	if err != nil {
-		return "", err
+		return "", blob.Ref{}, err
 	}
 	shareRoot, err := cl.ShareRoot()
 	if err != nil {
-		return "", err
+		return "", blob.Ref{}, err
 	}
-	if isDir {
-		return fmt.Sprintf("%s%s", shareRoot, claim), nil
+	if kind != shareKindFile {
+		return fmt.Sprintf("%s%s", shareRoot, claim), claim, nil
+	}
+	return fmt.Sprintf("%s%s?via=%s&assemble=1", shareRoot, target, claim), claim, nil
+}
+
+// shareAuthType returns the share claim's authType for a target of the
+// given kind. Files and dirs use ShareHaveRef: the handler grants access by
+// walking the blobs the target statically embeds. A permanode's current
+// content, though, is a mutable, claim-derived relationship (its
+// camliContent attribute), not a blob the permanode itself embeds, so a
+// haveref claim's transitive walk would never reach it; permanode targets
+// instead use ShareSearch, which tells the handler to resolve the target
+// through the index.
+func shareAuthType(kind string) string {
+	if kind == shareKindPermanode {
+		return schema.ShareSearch
 	}
-	return fmt.Sprintf("%s%s?via=%s&assemble=1", shareRoot, target, claim), nil
+	return schema.ShareHaveRef
 }
 
-// newShareClaim creates, signs, and uploads a transitive haveref share claim
-// for sharing the target item. It returns the ref of the claim.
-func newShareClaim(cl *client.Client, target blob.Ref) (blob.Ref, error) {
+// newShareClaim creates, signs, and uploads a share claim for sharing the
+// target item, using the authType appropriate for kind (see shareAuthType).
+// It returns the ref of the claim. The claim is transitive (granting access
+// to blobs the target references) only if opts.Transitive is true;
+// TestSharingTransitiveSafety on the handler side relies on non-transitive
+// claims rejecting "?via=" chains. If opts.Expires is non-zero, the claim
+// carries an expiration time that the share handler enforces. If
+// opts.Passphrase is non-empty, it is bcrypt-hashed into the claim's
+// authDigest so that the plaintext never leaves this function.
+func newShareClaim(ctx context.Context, cl *client.Client, target blob.Ref, kind string, opts ShareOptions) (blob.Ref, error) {
 	var claim blob.Ref
 	signer, err := cl.ServerPublicKeyBlobRef()
 	if err != nil {
 		return claim, fmt.Errorf("could not get signer: %v", err)
 	}
-	shareSchema := schema.NewShareRef(schema.ShareHaveRef, true)
+	shareSchema := schema.NewShareRef(shareAuthType(kind), opts.Transitive)
 	shareSchema.SetShareTarget(target)
+	if opts.Expires > 0 {
+		shareSchema.SetShareExpiration(time.Now().Add(opts.Expires))
+	}
+	if opts.Passphrase != "" {
+		digest, err := bcrypt.GenerateFromPassword([]byte(opts.Passphrase), bcrypt.DefaultCost)
+		if err != nil {
+			return claim, fmt.Errorf("could not hash share passphrase: %v", err)
+		}
+		shareSchema.SetShareAuthDigest(schema.ShareAuthBcrypt, string(digest))
+	}
 	unsignedClaim, err := shareSchema.SetSigner(signer).JSON()
 	if err != nil {
 		return claim, fmt.Errorf("could not create unsigned share claim: %v", err)
 	}
-	signedClaim, err := cl.Sign(context.TODO(), "", strings.NewReader("json="+unsignedClaim))
+	signedClaim, err := cl.Sign(ctx, "", strings.NewReader("json="+unsignedClaim))
 	if err != nil {
 		return claim, fmt.Errorf("could not get signed share claim: %v", err)
 	}
-	sbr, err := cl.Upload(context.TODO(), client.NewUploadHandleFromString(string(signedClaim)))
+	sbr, err := cl.Upload(ctx, client.NewUploadHandleFromString(string(signedClaim)))
 	if err != nil {
 		return claim, fmt.Errorf("could not upload share claim: %v", err)
 	}
@@ -0,0 +1,73 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharebutton
+
+import (
+	"testing"
+
+	"perkeep.org/pkg/schema"
+)
+
+func TestItemKind(t *testing.T) {
+	tests := []struct {
+		item    SharedItem
+		want    string
+		wantErr bool
+	}{
+		{SharedItem{"type": "file"}, shareKindFile, false},
+		{SharedItem{"type": "dir"}, shareKindDir, false},
+		{SharedItem{"type": "permanode"}, shareKindPermanode, false},
+		{SharedItem{"type": "bogus"}, "", true},
+		{SharedItem{"isDir": "true"}, shareKindDir, false},
+		{SharedItem{"isDir": "false"}, shareKindFile, false},
+		{SharedItem{"isDir": "not-a-bool"}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := itemKind(tt.item)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("itemKind(%v) error = %v, wantErr %v", tt.item, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("itemKind(%v) = %q, want %q", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultTransitive(t *testing.T) {
+	if defaultTransitive(shareKindFile) {
+		t.Error("a single file should default to non-transitive")
+	}
+	if !defaultTransitive(shareKindDir) {
+		t.Error("a dir should default to transitive")
+	}
+	if !defaultTransitive(shareKindPermanode) {
+		t.Error("a permanode should default to transitive")
+	}
+}
+
+func TestShareAuthType(t *testing.T) {
+	if got := shareAuthType(shareKindFile); got != schema.ShareHaveRef {
+		t.Errorf("shareAuthType(file) = %q, want %q", got, schema.ShareHaveRef)
+	}
+	if got := shareAuthType(shareKindDir); got != schema.ShareHaveRef {
+		t.Errorf("shareAuthType(dir) = %q, want %q", got, schema.ShareHaveRef)
+	}
+	if got := shareAuthType(shareKindPermanode); got != schema.ShareSearch {
+		t.Errorf("shareAuthType(permanode) = %q, want %q — a permanode's content is claim-derived and unreachable by a haveref blob walk", got, schema.ShareSearch)
+	}
+}
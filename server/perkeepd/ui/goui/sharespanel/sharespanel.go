@@ -0,0 +1,185 @@
+/*
+Copyright 2026 The Perkeep Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sharespanel provides a sidebar panel, peer to sharebutton, that
+// lists the share claims signed by this user and lets them be revoked.
+package sharespanel
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopherjs/gopherjs/js"
+
+	"perkeep.org/pkg/auth"
+	"perkeep.org/pkg/client"
+
+	"myitcv.io/react"
+)
+
+//go:generate reactGen
+
+// New returns the panel element. It should be used as the entry point, to
+// create the needed React element.
+//
+// key is the id for when the panel is in a list, see
+// https://facebook.github.io/react/docs/lists-and-keys.html
+//
+// config is the web UI config that was fetched from the server.
+//
+// cbs is a wrapper around the callback functions required by this component.
+func New(key string, config map[string]string, cbs *Callbacks) react.Element {
+	if config == nil {
+		fmt.Println("Nil config for SharesPanel")
+		return nil
+	}
+	shareRoot, ok := config["shareRoot"]
+	if !ok || shareRoot == "" {
+		// Server has no share handler.
+		return nil
+	}
+	authToken, ok := config["authToken"]
+	if !ok {
+		fmt.Println("No authToken in config for SharesPanel")
+		return nil
+	}
+	if key == "" {
+		key = "sharesPanel"
+	}
+	if cbs == nil {
+		fmt.Println("Nil callbacks for SharesPanel")
+		return nil
+	}
+
+	props := SharesPanelProps{
+		key:       key,
+		callbacks: cbs,
+		authToken: authToken,
+	}
+	return SharesPanel(props)
+}
+
+// Callbacks defines the callbacks that must be provided when creating a
+// SharesPanel instance.
+type Callbacks struct {
+	o *js.Object
+
+	// ShowShares renders the given shares in the panel.
+	ShowShares func(shares []Share) `js:"showShares"`
+}
+
+// Share describes one share claim signed by this user, as surfaced by
+// client.ListShares.
+type Share struct {
+	// ClaimRef is the blobRef of the signed share claim.
+	ClaimRef string `js:"claimRef"`
+	// Target is the blobRef of the shared file, dir, or permanode.
+	Target string `js:"target"`
+	// Created is the RFC3339 time at which the claim was signed.
+	Created string `js:"created"`
+	// Expires is the RFC3339 time at which the claim stops being valid, or
+	// the empty string if the share never expires.
+	Expires string `js:"expires"`
+	// Clicks is the number of times the share has been served, if the
+	// indexer tracks it. -1 means unknown.
+	Clicks int `js:"clicks"`
+}
+
+// SharesPanelProps are the properties of a SharesPanel.
+type SharesPanelProps struct {
+	key string
+
+	callbacks *Callbacks
+
+	authToken string
+}
+
+// SharesPanelDef is the definition for the panel, that Renders as nothing: the
+// actual list markup lives in the web UI's own templates, driven by
+// Callbacks.ShowShares.
+type SharesPanelDef struct {
+	react.ComponentDef
+}
+
+func SharesPanel(p SharesPanelProps) *SharesPanelElem {
+	return buildSharesPanelElem(p)
+}
+
+func (d SharesPanelDef) Render() react.Element {
+	return react.Div(nil)
+}
+
+// ComponentDidMount fetches the current shares as soon as the panel appears.
+func (d SharesPanelDef) ComponentDidMount() {
+	go d.refresh()
+}
+
+func (d SharesPanelDef) refresh() {
+	shares, err := d.listShares()
+	if err != nil {
+		fmt.Println("sharespanel: could not list shares:", err)
+		return
+	}
+	d.Props().callbacks.ShowShares(shares)
+}
+
+func (d SharesPanelDef) listShares() ([]Share, error) {
+	am, err := auth.NewTokenAuth(d.Props().authToken)
+	if err != nil {
+		return nil, fmt.Errorf("error setting up auth for share listing: %v", err)
+	}
+	cl, err := client.New(client.OptionAuthMode(am))
+	if err != nil {
+		return nil, err
+	}
+	claims, err := cl.ListShares(context.TODO())
+	if err != nil {
+		return nil, fmt.Errorf("could not list shares: %v", err)
+	}
+	shares := make([]Share, 0, len(claims))
+	for _, c := range claims {
+		shares = append(shares, Share{
+			ClaimRef: c.ClaimRef.String(),
+			Target:   c.Target.String(),
+			Created:  c.Created.Format("2006-01-02T15:04:05Z07:00"),
+			Expires:  c.Expires,
+			Clicks:   c.Clicks,
+		})
+	}
+	return shares, nil
+}
+
+// Revoke uploads a signed delete claim for claimRef, so that the share
+// handler stops honoring it, and then refreshes the panel.
+func (d SharesPanelDef) Revoke(claimRef string) {
+	go func() {
+		am, err := auth.NewTokenAuth(d.Props().authToken)
+		if err != nil {
+			fmt.Println("sharespanel: error setting up auth for revoke:", err)
+			return
+		}
+		cl, err := client.New(client.OptionAuthMode(am))
+		if err != nil {
+			fmt.Println("sharespanel: could not create client for revoke:", err)
+			return
+		}
+		if err := cl.RevokeShare(context.TODO(), claimRef); err != nil {
+			fmt.Println("sharespanel: could not revoke share:", err)
+			return
+		}
+		d.refresh()
+	}()
+}